@@ -0,0 +1,106 @@
+package gzap
+
+import (
+	"bytes"
+	"io"
+	"mime"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// WithBodyContentTypes optional allowlist of request/response Content-Type
+// values to capture, e.g. "application/json", "application/xml", "text/*".
+// A payload whose Content-Type matches none of the entries is skipped
+// entirely, so binary bodies (images, octet-streams, ...) are never buffered.
+// default: nil, every content type is captured.
+func WithBodyContentTypes(allow ...string) Option {
+	return func(c *Config) {
+		c.bodyContentTypes = allow
+	}
+}
+
+func bodyContentTypeAllowed(cfg *Config, contentType string) bool {
+	if len(cfg.bodyContentTypes) == 0 {
+		return true
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	for _, allow := range cfg.bodyContentTypes {
+		if strings.HasSuffix(allow, "/*") {
+			if strings.HasPrefix(mediaType, strings.TrimSuffix(allow, "*")) {
+				return true
+			}
+			continue
+		}
+		if mediaType == allow {
+			return true
+		}
+	}
+	return false
+}
+
+// boundedBuffer is a bytes.Buffer capped at limit: bytes past the cap are
+// counted but discarded and truncated is set, instead of growing without
+// bound. limit<=0 means unlimited, matching Config.limit.
+type boundedBuffer struct {
+	buf       bytes.Buffer
+	limit     int
+	total     int
+	truncated bool
+}
+
+// Write implements io.Writer. It never returns an error; bytes past limit
+// are simply dropped.
+func (b *boundedBuffer) Write(p []byte) (int, error) {
+	b.total += len(p)
+	if b.limit <= 0 {
+		return b.buf.Write(p)
+	}
+	remain := b.limit - b.buf.Len()
+	if remain <= 0 {
+		b.truncated = true
+		return len(p), nil
+	}
+	if len(p) > remain {
+		b.buf.Write(p[:remain])
+		b.truncated = true
+		return len(p), nil
+	}
+	return b.buf.Write(p)
+}
+
+func (b *boundedBuffer) String() string { return b.buf.String() }
+
+// teeReadCloser pairs a tee'd Reader with the original body's Closer, so
+// wrapping c.Request.Body still satisfies io.ReadCloser.
+type teeReadCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// bodyWriter duplicates everything written to the response into a bounded
+// buffer so the response body can be logged. It honors cfg's content-type
+// allowlist on the copy itself, not just on the eventual read-out, so a
+// disallowed content type (e.g. a binary download) is never buffered at all.
+type bodyWriter struct {
+	gin.ResponseWriter
+	dupBody *boundedBuffer
+	cfg     *Config
+}
+
+func (w *bodyWriter) Write(b []byte) (int, error) {
+	if bodyContentTypeAllowed(w.cfg, w.Header().Get("Content-Type")) {
+		_, _ = w.dupBody.Write(b)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *bodyWriter) WriteString(s string) (int, error) {
+	if bodyContentTypeAllowed(w.cfg, w.Header().Get("Content-Type")) {
+		_, _ = w.dupBody.Write([]byte(s))
+	}
+	return w.ResponseWriter.WriteString(s)
+}
@@ -0,0 +1,117 @@
+package gzap
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestBoundedBufferTruncatesAtLimit(t *testing.T) {
+	b := &boundedBuffer{limit: 5}
+
+	n, err := b.Write([]byte("hello world"))
+	if err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if n != len("hello world") {
+		t.Errorf("n = %d, want %d", n, len("hello world"))
+	}
+	if got, want := b.String(), "hello"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+	if !b.truncated {
+		t.Error("truncated = false, want true")
+	}
+	if b.total != len("hello world") {
+		t.Errorf("total = %d, want %d", b.total, len("hello world"))
+	}
+}
+
+func TestBoundedBufferUnlimited(t *testing.T) {
+	b := &boundedBuffer{limit: 0}
+	if _, err := b.Write([]byte("hello world")); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if b.truncated {
+		t.Error("truncated = true, want false for an unlimited buffer")
+	}
+	if got, want := b.String(), "hello world"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
+
+func TestBoundedBufferWritesPastLimitAreDropped(t *testing.T) {
+	b := &boundedBuffer{limit: 3}
+	b.Write([]byte("abc"))
+	b.Write([]byte("def"))
+
+	if got, want := b.String(), "abc"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+	if !b.truncated {
+		t.Error("truncated = false, want true")
+	}
+	if b.total != 6 {
+		t.Errorf("total = %d, want 6", b.total)
+	}
+}
+
+func TestBodyWriterSkipsCopyForDisallowedContentType(t *testing.T) {
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+
+	dup := &boundedBuffer{}
+	bw := &bodyWriter{ResponseWriter: c.Writer, dupBody: dup, cfg: &Config{bodyContentTypes: []string{"application/json"}}}
+
+	bw.Header().Set("Content-Type", "application/octet-stream")
+	if _, err := bw.Write([]byte("binary payload")); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if dup.String() != "" {
+		t.Errorf("dupBody = %q, want empty: disallowed content type must not be buffered", dup.String())
+	}
+	if rec.Body.String() != "binary payload" {
+		t.Errorf("response body = %q, want %q: the response itself must still be written", rec.Body.String(), "binary payload")
+	}
+}
+
+func TestBodyWriterCopiesAllowedContentType(t *testing.T) {
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+
+	dup := &boundedBuffer{}
+	bw := &bodyWriter{ResponseWriter: c.Writer, dupBody: dup, cfg: &Config{bodyContentTypes: []string{"application/json"}}}
+
+	bw.Header().Set("Content-Type", "application/json")
+	if _, err := bw.WriteString(`{"ok":true}`); err != nil {
+		t.Fatalf("WriteString: %v", err)
+	}
+	if dup.String() != `{"ok":true}` {
+		t.Errorf("dupBody = %q, want %q", dup.String(), `{"ok":true}`)
+	}
+}
+
+func TestBodyContentTypeAllowed(t *testing.T) {
+	tests := []struct {
+		name        string
+		allow       []string
+		contentType string
+		want        bool
+	}{
+		{"no allowlist accepts everything", nil, "application/octet-stream", true},
+		{"exact match", []string{"application/json"}, "application/json; charset=utf-8", true},
+		{"exact mismatch", []string{"application/json"}, "application/xml", false},
+		{"wildcard match", []string{"text/*"}, "text/plain", true},
+		{"wildcard mismatch", []string{"text/*"}, "application/json", false},
+		{"malformed content type rejected", []string{"application/json"}, "not a content type;;;", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{bodyContentTypes: tt.allow}
+			if got := bodyContentTypeAllowed(cfg, tt.contentType); got != tt.want {
+				t.Errorf("bodyContentTypeAllowed(%q) = %v, want %v", tt.contentType, got, tt.want)
+			}
+		})
+	}
+}
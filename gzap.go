@@ -4,14 +4,13 @@
 package gzap
 
 import (
-	"bytes"
+	"fmt"
 	"io"
 	"mime"
 	"net"
 	"net/http"
 	"net/http/httputil"
 	"os"
-	"runtime/debug"
 	"strings"
 	"time"
 
@@ -54,6 +53,44 @@ func WithBodyLimit(limit int) Option {
 	}
 }
 
+// WithBodyLevel optional level gate for request/response body capture: the
+// body is only read/duplicated when a log line at lvl would actually be
+// written by the underlying core, so a disabled level does not pay for the
+// extra allocations and reads.
+// default: nil, body capture is not gated by level.
+func WithBodyLevel(lvl zapcore.Level) Option {
+	return func(c *Config) {
+		c.bodyLevel = &lvl
+	}
+}
+
+// WithRequestHeaders optional allowlist of request header names to capture
+// into FieldRequestHeaders. Authorization, Cookie, Set-Cookie and
+// Proxy-Authorization are always redacted to "***", even if listed.
+func WithRequestHeaders(names ...string) Option {
+	return func(c *Config) {
+		c.requestHeaders = names
+	}
+}
+
+// WithResponseHeaders optional allowlist of response header names to capture
+// into FieldResponseHeaders. Authorization, Cookie, Set-Cookie and
+// Proxy-Authorization are always redacted to "***", even if listed.
+func WithResponseHeaders(names ...string) Option {
+	return func(c *Config) {
+		c.responseHeaders = names
+	}
+}
+
+// WithHeaderRedactor optional custom redaction of a captured header's value,
+// e.g. to mask PII. It runs in addition to the built-in redaction of
+// Authorization, Cookie, Set-Cookie and Proxy-Authorization.
+func WithHeaderRedactor(f func(name, value string) string) Option {
+	return func(c *Config) {
+		c.headerRedactor = f
+	}
+}
+
 // WithSkipRequestBody optional custom skip request body logging option.
 func WithSkipRequestBody(f func(c *gin.Context) bool) Option {
 	return func(c *Config) {
@@ -72,6 +109,33 @@ func WithSkipResponseBody(f func(c *gin.Context) bool) Option {
 	}
 }
 
+// WithPanicHook optional hook invoked with the recovered panic value before
+// Recovery logs it, letting callers attach request-scoped side effects (e.g.
+// recording the error against a tracing span) without Recovery itself taking
+// on that dependency. Not invoked for the broken-pipe case, which skips
+// logging detail for the same reason it skips the stack trace.
+func WithPanicHook(f func(c *gin.Context, err interface{})) Option {
+	return func(c *Config) {
+		if f != nil {
+			c.panicHook = f
+		}
+	}
+}
+
+// WithAccessHook optional hook invoked once per request handled by Logger,
+// after the handler chain completes, regardless of cfg.skipLogging or whether
+// the resolved level is actually enabled on logger. Use it for request-scoped
+// side effects that must happen independently of the logging decision (e.g.
+// emitting a tracing span event), as opposed to WithCustomFields, whose
+// functions only run when a log line is actually going to be written.
+func WithAccessHook(f func(c *gin.Context)) Option {
+	return func(c *Config) {
+		if f != nil {
+			c.accessHook = f
+		}
+	}
+}
+
 // WithUseLoggerLevel optional use logging level.
 func WithUseLoggerLevel(f func(c *gin.Context) zapcore.Level) Option {
 	return func(c *Config) {
@@ -103,6 +167,10 @@ const (
 	FieldLatency
 	FieldRequestBody
 	FieldResponseBody
+	FieldTraceID
+	FieldRequestHeaders
+	FieldResponseHeaders
+	FieldSpanID
 	fieldMaxLen
 )
 
@@ -121,9 +189,35 @@ type Config struct {
 	// 	zap.WarnLevel: when status >= http.StatusBadRequest && status <= http.StatusUnavailableForLegalReasons
 	//  zap.InfoLevel: otherwise.
 	useLoggerLevel func(c *gin.Context) zapcore.Level
-	enableBody     bool                // enable request/response body
-	limit          int                 // <=0: mean not limit
-	field          [fieldMaxLen]string // log field names
+	// traceID/spanID resolve the correlation/trace ID and span ID attached to
+	// the request-scoped logger and the access log line.
+	traceID          func(c *gin.Context) string
+	spanID           func(c *gin.Context) string
+	enableBody       bool                // enable request/response body
+	bodyLevel        *zapcore.Level      // nil: capture body regardless of level
+	bodyContentTypes []string            // nil: capture every content type
+	limit            int                 // <=0: mean not limit
+	field            [fieldMaxLen]string // log field names
+	// requestHeaders/responseHeaders list the header names collected into
+	// FieldRequestHeaders/FieldResponseHeaders.
+	requestHeaders  []string
+	responseHeaders []string
+	// headerRedactor optionally rewrites a collected header value, e.g. to mask PII.
+	// Authorization, Cookie, Set-Cookie and Proxy-Authorization are always redacted,
+	// regardless of headerRedactor.
+	headerRedactor func(name, value string) string
+	// stackSkip/stackDepth tune CaptureStack when Recovery captures a panic's stack.
+	stackSkip  int
+	stackDepth int
+	// routeSampling, sampleErrors, sample5xx and sampler implement WithRouteSampling.
+	routeSampling map[string]RouteSampleConfig
+	sampleErrors  bool
+	sample5xx     bool
+	sampler       *routeSampler
+	// panicHook implements WithPanicHook.
+	panicHook func(c *gin.Context, err interface{})
+	// accessHook implements WithAccessHook.
+	accessHook func(c *gin.Context)
 }
 
 func skipRequestBody(c *gin.Context) bool {
@@ -159,8 +253,13 @@ func newConfig() Config {
 		skipRequestBody:  func(c *gin.Context) bool { return false },
 		skipResponseBody: func(c *gin.Context) bool { return false },
 		useLoggerLevel:   useLoggerLevel,
+		traceID:          traceID,
+		spanID:           spanID,
 		enableBody:       false,
 		limit:            0,
+		stackSkip:        defaultStackSkip,
+		stackDepth:       defaultStackDepth,
+		sampler:          newRouteSampler(),
 		field: [fieldMaxLen]string{
 			"status",
 			"method",
@@ -172,6 +271,10 @@ func newConfig() Config {
 			"latency",
 			"requestBody",
 			"responseBody",
+			"traceID",
+			"requestHeaders",
+			"responseHeaders",
+			"spanID",
 		},
 	}
 }
@@ -186,25 +289,24 @@ func Logger(logger *zap.Logger, opts ...Option) gin.HandlerFunc {
 		opt(&cfg)
 	}
 	return func(c *gin.Context) {
-		respBodyBuilder := &strings.Builder{}
+		respBodyBuilder := &boundedBuffer{limit: cfg.limit}
+		reqBodyBuilder := &boundedBuffer{limit: cfg.limit}
 		reqBody := "skip request body"
+		reqBodyCaptured := false
 
-		if cfg.enableBody {
-			c.Writer = &bodyWriter{ResponseWriter: c.Writer, dupBody: respBodyBuilder}
-			if hasSkipRequestBody := skipRequestBody(c) || cfg.skipRequestBody(c); !hasSkipRequestBody {
-				reqBodyBuf, err := io.ReadAll(c.Request.Body)
-				if err != nil {
-					c.String(http.StatusInternalServerError, err.Error())
-					c.Abort()
-					return
-				}
-				c.Request.Body.Close()
-				c.Request.Body = io.NopCloser(bytes.NewBuffer(reqBodyBuf))
-				if cfg.limit > 0 && len(reqBodyBuf) >= cfg.limit {
-					reqBody = "larger request body"
-				} else {
-					reqBody = string(reqBodyBuf)
-				}
+		captureBody := cfg.enableBody
+		if captureBody && cfg.bodyLevel != nil && logger.Check(*cfg.bodyLevel, "logging") == nil {
+			captureBody = false
+		}
+
+		if captureBody {
+			c.Writer = &bodyWriter{ResponseWriter: c.Writer, dupBody: respBodyBuilder, cfg: &cfg}
+			hasSkipRequestBody := skipRequestBody(c) || cfg.skipRequestBody(c) ||
+				!bodyContentTypeAllowed(&cfg, c.Request.Header.Get("Content-Type"))
+			if !hasSkipRequestBody {
+				body := c.Request.Body
+				c.Request.Body = teeReadCloser{Reader: io.TeeReader(body, reqBodyBuilder), Closer: body}
+				reqBodyCaptured = true
 			}
 		}
 
@@ -213,20 +315,52 @@ func Logger(logger *zap.Logger, opts ...Option) gin.HandlerFunc {
 		path := c.Request.URL.Path
 		query := c.Request.URL.RawQuery
 
+		tid := cfg.traceID(c)
+		sid := cfg.spanID(c)
+		c.Writer.Header().Set(TraceIDHeader, tid)
+		c.Writer.Header().Set(SpanIDHeader, sid)
+		c.Set(CtxLoggerKey, logger.With(
+			zap.String(cfg.field[FieldMethod], c.Request.Method),
+			zap.String(cfg.field[FieldPath], path),
+			zap.String(cfg.field[FieldRoute], c.FullPath()),
+			zap.String(cfg.field[FieldIP], c.ClientIP()),
+			zap.String(cfg.field[FieldTraceID], tid),
+			zap.String(cfg.field[FieldSpanID], sid),
+		))
+
 		defer func() {
+			if cfg.accessHook != nil {
+				cfg.accessHook(c)
+			}
 			if cfg.skipLogging(c) {
 				return
 			}
 			var level zapcore.Level
-
-			fieldLength := 8 + len(cfg.customFields) + 2
 			if len(c.Errors) > 0 {
 				level = zapcore.ErrorLevel
-				fieldLength += len(c.Errors)
 			} else {
 				level = cfg.useLoggerLevel(c)
 			}
+			level = cfg.sample(c.Request.Method, c.FullPath(), level, len(c.Errors) > 0, c.Writer.Status())
 
+			ce := logger.Check(level, "logging")
+			if ce == nil {
+				return
+			}
+
+			fieldLength := 10 + len(cfg.customFields) + len(c.Errors)
+			if len(cfg.requestHeaders) > 0 {
+				fieldLength++
+			}
+			if len(cfg.responseHeaders) > 0 {
+				fieldLength++
+			}
+			if reqBodyBuilder.truncated {
+				fieldLength++
+			}
+			if respBodyBuilder.truncated {
+				fieldLength++
+			}
 			fields := make([]zap.Field, 0, fieldLength)
 			fields = append(fields,
 				zap.Int(cfg.field[FieldStatus], c.Writer.Status()),
@@ -237,20 +371,49 @@ func Logger(logger *zap.Logger, opts ...Option) gin.HandlerFunc {
 				zap.String(cfg.field[FieldIP], c.ClientIP()),
 				zap.String(cfg.field[FieldUserAgent], c.Request.UserAgent()),
 				zap.Duration(cfg.field[FieldLatency], time.Since(start)),
+				zap.String(cfg.field[FieldTraceID], tid),
+				zap.String(cfg.field[FieldSpanID], sid),
 			)
-			if cfg.enableBody {
+			if captureBody {
+				if reqBodyCaptured {
+					// The handler may not have read the request body to EOF
+					// (e.g. it bailed out on validation, or never touched
+					// the body at all) - drain whatever is left through the
+					// tee so reqBodyBuilder reflects the full body rather
+					// than only the part the handler happened to consume.
+					_, _ = io.Copy(io.Discard, c.Request.Body)
+					reqBody = reqBodyBuilder.String()
+				}
 				respBody := "skip response body"
-				if hasSkipResponseBody := skipResponseBody(c) || cfg.skipResponseBody(c); !hasSkipResponseBody {
-					if cfg.limit > 0 && respBodyBuilder.Len() >= cfg.limit {
-						respBody = "larger response body"
-					} else {
-						respBody = respBodyBuilder.String()
-					}
+				hasSkipResponseBody := skipResponseBody(c) || cfg.skipResponseBody(c) ||
+					!bodyContentTypeAllowed(&cfg, c.Writer.Header().Get("Content-Type"))
+				if !hasSkipResponseBody {
+					respBody = respBodyBuilder.String()
 				}
 				fields = append(fields,
 					zap.String(cfg.field[FieldRequestBody], reqBody),
 					zap.String(cfg.field[FieldResponseBody], respBody),
 				)
+				if reqBodyBuilder.truncated {
+					fields = append(fields, zap.Bool("requestBodyTruncated", true))
+				}
+				if respBodyBuilder.truncated {
+					fields = append(fields, zap.Bool("responseBodyTruncated", true))
+				}
+			}
+			if len(cfg.requestHeaders) > 0 {
+				fields = append(fields, zap.Object(cfg.field[FieldRequestHeaders], headerSet{
+					header: c.Request.Header,
+					names:  cfg.requestHeaders,
+					cfg:    &cfg,
+				}))
+			}
+			if len(cfg.responseHeaders) > 0 {
+				fields = append(fields, zap.Object(cfg.field[FieldResponseHeaders], headerSet{
+					header: c.Writer.Header(),
+					names:  cfg.responseHeaders,
+					cfg:    &cfg,
+				}))
 			}
 			for _, fieldFunc := range cfg.customFields {
 				fields = append(fields, fieldFunc(c))
@@ -260,7 +423,7 @@ func Logger(logger *zap.Logger, opts ...Option) gin.HandlerFunc {
 					fields = append(fields, zap.Error(e))
 				}
 			}
-			logger.Log(level, "logging", fields...)
+			ce.Write(fields...)
 
 		}()
 
@@ -280,7 +443,7 @@ func Recovery(logger *zap.Logger, stack bool, opts ...Option) gin.HandlerFunc {
 	}
 	if stack {
 		cfg.customFields = append(cfg.customFields, func(c *gin.Context) zap.Field {
-			return zap.ByteString("stack", debug.Stack())
+			return zap.Array("stack", frames(CaptureStack(cfg.stackSkip, cfg.stackDepth)))
 		})
 	}
 	return func(c *gin.Context) {
@@ -298,27 +461,37 @@ func Recovery(logger *zap.Logger, stack bool, opts ...Option) gin.HandlerFunc {
 					}
 				}
 
-				httpRequest, _ := httputil.DumpRequest(c.Request, false)
 				if brokenPipe {
-					logger.Error(c.Request.URL.Path,
-						zap.Any("error", err),
-						zap.ByteString("request", httpRequest),
-					)
+					if ce := logger.Check(zap.ErrorLevel, c.Request.URL.Path); ce != nil {
+						httpRequest, _ := httputil.DumpRequest(c.Request, false)
+						ce.Write(
+							zap.Any("error", err),
+							zap.ByteString("request", httpRequest),
+						)
+					}
 					// If the connection is dead, we can't write a status to it.
 					_ = c.Error(err.(error))
 					c.Abort()
 					return
 				}
 
-				fields := make([]zap.Field, 0, 2+len(cfg.customFields))
-				fields = append(fields,
-					zap.Any("error", err),
-					zap.ByteString("request", httpRequest),
-				)
-				for _, field := range cfg.customFields {
-					fields = append(fields, field(c))
+				if cfg.panicHook != nil {
+					cfg.panicHook(c, err)
+				}
+
+				if ce := logger.Check(zap.ErrorLevel, "recovery from panic"); ce != nil {
+					httpRequest, _ := httputil.DumpRequest(c.Request, false)
+					fields := make([]zap.Field, 0, 3+len(cfg.customFields))
+					fields = append(fields,
+						zap.Any("error", err),
+						zap.String("panic_type", fmt.Sprintf("%T", err)),
+						zap.ByteString("request", httpRequest),
+					)
+					for _, field := range cfg.customFields {
+						fields = append(fields, field(c))
+					}
+					ce.Write(fields...)
 				}
-				logger.Error("recovery from panic", fields...)
 				c.AbortWithStatus(http.StatusInternalServerError)
 			}
 		}()
@@ -326,21 +499,6 @@ func Recovery(logger *zap.Logger, stack bool, opts ...Option) gin.HandlerFunc {
 	}
 }
 
-type bodyWriter struct {
-	gin.ResponseWriter
-	dupBody *strings.Builder
-}
-
-func (w *bodyWriter) Write(b []byte) (int, error) {
-	w.dupBody.Write(b)
-	return w.ResponseWriter.Write(b)
-}
-
-func (w *bodyWriter) WriteString(s string) (int, error) {
-	w.dupBody.WriteString(s)
-	return w.ResponseWriter.WriteString(s)
-}
-
 // Any custom immutable any field
 func Any(key string, value interface{}) func(c *gin.Context) zap.Field {
 	field := zap.Any(key, value)
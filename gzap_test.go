@@ -0,0 +1,124 @@
+package gzap
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// TestLoggerAccessHookFiresEvenWhenCheckNeverSucceeds guards against
+// regressing access-hook side effects (e.g. emitting a tracing span event)
+// into something that only runs when a log line is actually written: a
+// hook registered via WithAccessHook must run even when the logger's core
+// never enables any level, so logger.Check always returns nil.
+func TestLoggerAccessHookFiresEvenWhenCheckNeverSucceeds(t *testing.T) {
+	logger := zap.New(zapcore.NewNopCore())
+
+	var fired bool
+	handler := Logger(logger, WithAccessHook(func(c *gin.Context) {
+		fired = true
+	}))
+
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.Use(handler)
+	engine.GET("/ping", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+	rec := httptest.NewRecorder()
+	engine.ServeHTTP(rec, req)
+
+	if !fired {
+		t.Error("access hook did not fire, want it to run regardless of logger.Check()")
+	}
+}
+
+func TestLoggerAccessHookFiresEvenWhenSkipLogging(t *testing.T) {
+	logger := zap.NewNop()
+
+	var fired bool
+	handler := Logger(logger,
+		WithSkipLogging(func(c *gin.Context) bool { return true }),
+		WithAccessHook(func(c *gin.Context) { fired = true }),
+	)
+
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.Use(handler)
+	engine.GET("/ping", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+	rec := httptest.NewRecorder()
+	engine.ServeHTTP(rec, req)
+
+	if !fired {
+		t.Error("access hook did not fire, want it to run regardless of WithSkipLogging")
+	}
+}
+
+// TestLoggerCapturesFullRequestBodyEvenWhenHandlerDoesNotReadIt guards
+// against only capturing as much of the request body as the handler
+// happened to read through the tee: a handler that never reads the body at
+// all must still see the full body in the requestBody field.
+func TestLoggerCapturesFullRequestBodyEvenWhenHandlerDoesNotReadIt(t *testing.T) {
+	core, logs := observer.New(zapcore.InfoLevel)
+	logger := zap.New(core)
+
+	handler := Logger(logger, WithEnableBody(true))
+
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.Use(handler)
+	engine.POST("/ping", func(c *gin.Context) {
+		// Never reads c.Request.Body.
+		c.Status(http.StatusOK)
+	})
+
+	body := `{"hello":"world"}`
+	req := httptest.NewRequest(http.MethodPost, "/ping", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	engine.ServeHTTP(rec, req)
+
+	entries := logs.All()
+	if len(entries) != 1 {
+		t.Fatalf("got %d log entries, want 1", len(entries))
+	}
+	got := entries[0].ContextMap()["requestBody"]
+	if got != body {
+		t.Errorf("requestBody = %q, want %q", got, body)
+	}
+}
+
+func TestLoggerSetsSpanIDHeaderFromTraceparent(t *testing.T) {
+	logger := zap.NewNop()
+	handler := Logger(logger)
+
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.Use(handler)
+	engine.GET("/ping", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	rec := httptest.NewRecorder()
+	engine.ServeHTTP(rec, req)
+
+	if got, want := rec.Header().Get(SpanIDHeader), "00f067aa0ba902b7"; got != want {
+		t.Errorf("SpanIDHeader = %q, want %q", got, want)
+	}
+	if got, want := rec.Header().Get(TraceIDHeader), "4bf92f3577b34da6a3ce929d0e0e4736"; got != want {
+		t.Errorf("TraceIDHeader = %q, want %q", got, want)
+	}
+}
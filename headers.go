@@ -0,0 +1,49 @@
+package gzap
+
+import (
+	"net/http"
+	"strings"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// sensitiveHeaders are always redacted when captured, regardless of
+// WithHeaderRedactor, since they routinely carry credentials.
+var sensitiveHeaders = map[string]bool{
+	"authorization":       true,
+	"cookie":              true,
+	"set-cookie":          true,
+	"proxy-authorization": true,
+}
+
+const redactedHeaderValue = "***"
+
+// headerSet implements zapcore.ObjectMarshaler, encoding the allowlisted
+// header names present on header, redacting sensitive values.
+type headerSet struct {
+	header http.Header
+	names  []string
+	cfg    *Config
+}
+
+// MarshalLogObject implements zapcore.ObjectMarshaler.
+func (h headerSet) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	for _, name := range h.names {
+		v := h.header.Get(name)
+		if v == "" {
+			continue
+		}
+		enc.AddString(name, h.redact(name, v))
+	}
+	return nil
+}
+
+func (h headerSet) redact(name, value string) string {
+	if sensitiveHeaders[strings.ToLower(name)] {
+		return redactedHeaderValue
+	}
+	if h.cfg.headerRedactor != nil {
+		return h.cfg.headerRedactor(name, value)
+	}
+	return value
+}
@@ -0,0 +1,83 @@
+package gzap
+
+import (
+	"net/http"
+	"testing"
+
+	"go.uber.org/zap/zapcore"
+)
+
+func TestHeaderSetRedaction(t *testing.T) {
+	header := http.Header{}
+	header.Set("Authorization", "Bearer secret")
+	header.Set("Cookie", "session=abc")
+	header.Set("X-Request-Id", "req-1")
+	header.Set("X-User-Email", "user@example.com")
+
+	cfg := &Config{
+		headerRedactor: func(name, value string) string {
+			if name == "X-User-Email" {
+				return "redacted@example.com"
+			}
+			return value
+		},
+	}
+	hs := headerSet{
+		header: header,
+		names:  []string{"Authorization", "Cookie", "X-Request-Id", "X-User-Email"},
+		cfg:    cfg,
+	}
+
+	enc := zapcore.NewMapObjectEncoder()
+	if err := hs.MarshalLogObject(enc); err != nil {
+		t.Fatalf("MarshalLogObject: %v", err)
+	}
+
+	want := map[string]interface{}{
+		"Authorization": redactedHeaderValue,
+		"Cookie":        redactedHeaderValue,
+		"X-Request-Id":  "req-1",
+		"X-User-Email":  "redacted@example.com",
+	}
+	for name, wantVal := range want {
+		if got := enc.Fields[name]; got != wantVal {
+			t.Errorf("field %q = %v, want %v", name, got, wantVal)
+		}
+	}
+}
+
+func TestHeaderSetSensitiveHeaderAlwaysRedactedDespiteCustomRedactor(t *testing.T) {
+	header := http.Header{}
+	header.Set("Authorization", "Bearer secret")
+
+	cfg := &Config{
+		headerRedactor: func(name, value string) string {
+			// A misconfigured custom redactor that would leak the value.
+			return value
+		},
+	}
+	hs := headerSet{header: header, names: []string{"Authorization"}, cfg: cfg}
+
+	enc := zapcore.NewMapObjectEncoder()
+	if err := hs.MarshalLogObject(enc); err != nil {
+		t.Fatalf("MarshalLogObject: %v", err)
+	}
+	if got := enc.Fields["Authorization"]; got != redactedHeaderValue {
+		t.Errorf("Authorization = %v, want %v", got, redactedHeaderValue)
+	}
+}
+
+func TestHeaderSetSkipsAbsentHeaders(t *testing.T) {
+	header := http.Header{}
+	header.Set("X-Present", "yes")
+
+	hs := headerSet{header: header, names: []string{"X-Present", "X-Absent"}, cfg: &Config{}}
+
+	enc := zapcore.NewMapObjectEncoder()
+	if err := hs.MarshalLogObject(enc); err != nil {
+		t.Fatalf("MarshalLogObject: %v", err)
+	}
+	if _, ok := enc.Fields["X-Absent"]; ok {
+		t.Error("X-Absent should not be encoded when the header is not present")
+	}
+}
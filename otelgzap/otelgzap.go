@@ -0,0 +1,97 @@
+// Package otelgzap wires gzap's access logging into OpenTelemetry tracing:
+// it attaches trace_id, span_id and trace_flags to every access log line,
+// emits an http.access span event carrying the request's method, route and
+// status code, and records recovered panics
+// against the request's span. It is kept separate from gzap so the core
+// module stays dependency-light.
+package otelgzap
+
+import (
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+
+	"github.com/things-go/gzap"
+)
+
+// accessEventName is the span event name used to mirror a completed access
+// log line onto its OpenTelemetry span.
+const accessEventName = "http.access"
+
+// WithOTelTrace returns a gzap.Option that appends trace_id, span_id and
+// trace_flags fields - sourced from the request's OpenTelemetry span - to
+// every access log line that actually gets written, and, independently of
+// whether a log line gets written, emits an http.access span event on any
+// span that is recording. The event carries only the request's method, route
+// and status code; the other access log fields (path, query, client IP,
+// latency, bodies, headers, custom fields) are not copied onto it. The span
+// event runs via gzap.WithAccessHook so
+// it fires even when logger's configured level or WithSkipLogging would
+// otherwise suppress the log line entirely - it is meant to be visible in the
+// tracing backend without double-instrumentation, not gated behind the log
+// line.
+//
+// Combine the fields with any other custom fields through
+// gzap.WithCustomFields, since the latter replaces rather than appends:
+//
+//	gzap.Logger(logger, gzap.WithCustomFields(append(otelgzap.Fields(), myField)...))
+func WithOTelTrace() gzap.Option {
+	return func(c *gzap.Config) {
+		gzap.WithCustomFields(Fields()...)(c)
+		gzap.WithAccessHook(emitAccessEvent)(c)
+	}
+}
+
+// Fields returns the custom field functions behind WithOTelTrace, for
+// combining with other gzap.WithCustomFields entries.
+func Fields() []func(c *gin.Context) zap.Field {
+	return []func(c *gin.Context) zap.Field{
+		func(c *gin.Context) zap.Field {
+			return zap.String("trace_id", spanContext(c).TraceID().String())
+		},
+		func(c *gin.Context) zap.Field {
+			return zap.String("span_id", spanContext(c).SpanID().String())
+		},
+		func(c *gin.Context) zap.Field {
+			return zap.String("trace_flags", spanContext(c).TraceFlags().String())
+		},
+	}
+}
+
+// WithPanicHook returns a gzap.Option for gzap.Recovery that records a
+// recovered panic against the request's OpenTelemetry span via
+// span.RecordError and span.SetStatus.
+func WithPanicHook() gzap.Option {
+	return gzap.WithPanicHook(func(c *gin.Context, err interface{}) {
+		span := trace.SpanFromContext(c.Request.Context())
+		if !span.IsRecording() {
+			return
+		}
+		e, ok := err.(error)
+		if !ok {
+			e = fmt.Errorf("%v", err)
+		}
+		span.RecordError(e)
+		span.SetStatus(codes.Error, e.Error())
+	})
+}
+
+func spanContext(c *gin.Context) trace.SpanContext {
+	return trace.SpanContextFromContext(c.Request.Context())
+}
+
+func emitAccessEvent(c *gin.Context) {
+	span := trace.SpanFromContext(c.Request.Context())
+	if !span.IsRecording() {
+		return
+	}
+	span.AddEvent(accessEventName, trace.WithAttributes(
+		attribute.String("http.method", c.Request.Method),
+		attribute.String("http.route", c.FullPath()),
+		attribute.Int("http.status_code", c.Writer.Status()),
+	))
+}
@@ -0,0 +1,144 @@
+package otelgzap
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+
+	"github.com/things-go/gzap"
+)
+
+func newRecordingSpanContext(t *testing.T) (context.Context, *tracetest.InMemoryExporter) {
+	t.Helper()
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	t.Cleanup(func() { _ = tp.Shutdown(context.Background()) })
+
+	ctx, _ := tp.Tracer("otelgzap_test").Start(context.Background(), "test-span")
+	return ctx, exporter
+}
+
+func ginContextWithTraceContext(ctx context.Context) *gin.Context {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+	c.Request = req.WithContext(ctx)
+	return c
+}
+
+func TestFieldsReadsRealSpanContext(t *testing.T) {
+	ctx, _ := newRecordingSpanContext(t)
+	c := ginContextWithTraceContext(ctx)
+
+	sc := spanContext(c)
+
+	fields := Fields()
+	if len(fields) != 3 {
+		t.Fatalf("got %d fields, want 3", len(fields))
+	}
+	got := map[string]string{}
+	for _, f := range fields {
+		field := f(c)
+		got[field.Key] = field.String
+	}
+	if got["trace_id"] != sc.TraceID().String() {
+		t.Errorf("trace_id = %q, want %q", got["trace_id"], sc.TraceID().String())
+	}
+	if got["span_id"] != sc.SpanID().String() {
+		t.Errorf("span_id = %q, want %q", got["span_id"], sc.SpanID().String())
+	}
+	if got["trace_flags"] != sc.TraceFlags().String() {
+		t.Errorf("trace_flags = %q, want %q", got["trace_flags"], sc.TraceFlags().String())
+	}
+}
+
+func TestEmitAccessEventRecordsEventOnRecordingSpan(t *testing.T) {
+	ctx, exporter := newRecordingSpanContext(t)
+	c := ginContextWithTraceContext(ctx)
+	c.Request.Method = http.MethodPost
+
+	emitAccessEvent(c)
+	trace.SpanFromContext(ctx).End()
+
+	spans := exporter.GetSpans()
+	if len(spans) != 1 {
+		t.Fatalf("got %d exported spans, want 1", len(spans))
+	}
+	events := spans[0].Events
+	if len(events) != 1 {
+		t.Fatalf("got %d events, want 1", len(events))
+	}
+	if events[0].Name != accessEventName {
+		t.Errorf("event name = %q, want %q", events[0].Name, accessEventName)
+	}
+}
+
+func TestEmitAccessEventNoopOnNonRecordingSpan(t *testing.T) {
+	// No span in context at all -> trace.SpanFromContext returns a no-op,
+	// non-recording span; emitAccessEvent must not panic and must be a no-op.
+	c := ginContextWithTraceContext(context.Background())
+	emitAccessEvent(c)
+}
+
+// TestWithPanicHookRecordsErrorAndSetsStatus drives WithPanicHook the way
+// gzap.Recovery actually invokes it - through a panicking handler - since
+// the hook func itself is only reachable via the gzap.Option it returns.
+func TestWithPanicHookRecordsErrorAndSetsStatus(t *testing.T) {
+	tests := []struct {
+		name        string
+		panicValue  interface{}
+		wantMessage string
+	}{
+		{"error value", panicError("boom"), "boom"},
+		{"non-error value gets wrapped", "not an error", "not an error"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, exporter := newRecordingSpanContext(t)
+
+			gin.SetMode(gin.TestMode)
+			engine := gin.New()
+			engine.Use(func(c *gin.Context) {
+				c.Request = c.Request.WithContext(ctx)
+				c.Next()
+			})
+			engine.Use(gzap.Recovery(zap.NewNop(), false, WithPanicHook()))
+			engine.GET("/panic", func(c *gin.Context) {
+				panic(tt.panicValue)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/panic", nil)
+			rec := httptest.NewRecorder()
+			engine.ServeHTTP(rec, req)
+
+			trace.SpanFromContext(ctx).End()
+
+			spans := exporter.GetSpans()
+			if len(spans) != 1 {
+				t.Fatalf("got %d exported spans, want 1", len(spans))
+			}
+			span := spans[0]
+			if span.Status.Code != codes.Error {
+				t.Errorf("status code = %v, want %v", span.Status.Code, codes.Error)
+			}
+			if span.Status.Description != tt.wantMessage {
+				t.Errorf("status description = %q, want %q", span.Status.Description, tt.wantMessage)
+			}
+			if len(span.Events) != 1 {
+				t.Fatalf("got %d events, want 1 (the recorded error)", len(span.Events))
+			}
+		})
+	}
+}
+
+type panicError string
+
+func (e panicError) Error() string { return string(e) }
@@ -0,0 +1,104 @@
+package gzap
+
+import (
+	"sync"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// RouteSampleConfig bounds log volume for a single route, mirroring
+// zapcore's sampler semantics: within each Tick window, the first Initial
+// log lines pass through, then only every Thereafter-th one does.
+type RouteSampleConfig struct {
+	Initial    int
+	Thereafter int
+	Tick       time.Duration
+}
+
+// WithRouteSampling optional per-route log sampling, keyed by
+// "METHOD fullpath" (e.g. "GET /healthz", using c.FullPath()). Requests that
+// exceed their route's budget for the current tick window are logged at
+// zapcore.DebugLevel instead of their resolved level.
+//
+// Requests with errors (len(c.Errors) > 0) or a 5xx status bypass sampling by
+// default; see WithSampleErrors and WithSample5xx to opt them in.
+func WithRouteSampling(routes map[string]RouteSampleConfig) Option {
+	return func(c *Config) {
+		c.routeSampling = routes
+	}
+}
+
+// WithSampleErrors optional override to also apply route sampling to
+// requests that produced an error. They bypass sampling by default.
+func WithSampleErrors(b bool) Option {
+	return func(c *Config) {
+		c.sampleErrors = b
+	}
+}
+
+// WithSample5xx optional override to also apply route sampling to 5xx
+// responses. They bypass sampling by default.
+func WithSample5xx(b bool) Option {
+	return func(c *Config) {
+		c.sample5xx = b
+	}
+}
+
+// routeSampler tracks per-route, per-level sample buckets across requests.
+type routeSampler struct {
+	mu      sync.Mutex
+	buckets map[string]*sampleBucket
+}
+
+type sampleBucket struct {
+	resetAt time.Time
+	count   int
+}
+
+func newRouteSampler() *routeSampler {
+	return &routeSampler{buckets: make(map[string]*sampleBucket)}
+}
+
+// allow reports whether a log line for key should pass, given cfg's budget
+// for the tick window key currently falls in.
+func (s *routeSampler) allow(key string, cfg RouteSampleConfig) bool {
+	now := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, ok := s.buckets[key]
+	if !ok || !now.Before(b.resetAt) {
+		b = &sampleBucket{resetAt: now.Add(cfg.Tick)}
+		s.buckets[key] = b
+	}
+	b.count++
+	if b.count <= cfg.Initial {
+		return true
+	}
+	return cfg.Thereafter > 0 && (b.count-cfg.Initial)%cfg.Thereafter == 0
+}
+
+// sample resolves level down to zapcore.DebugLevel when the request's route
+// has route sampling configured and its budget for the current tick window
+// is exceeded; it returns level unchanged otherwise.
+func (cfg *Config) sample(method, route string, level zapcore.Level, hasErrors bool, status int) zapcore.Level {
+	if len(cfg.routeSampling) == 0 {
+		return level
+	}
+	if hasErrors && !cfg.sampleErrors {
+		return level
+	}
+	if status >= 500 && status <= 599 && !cfg.sample5xx {
+		return level
+	}
+	routeCfg, ok := cfg.routeSampling[method+" "+route]
+	if !ok {
+		return level
+	}
+	if cfg.sampler.allow(method+" "+route+" "+level.String(), routeCfg) {
+		return level
+	}
+	return zapcore.DebugLevel
+}
@@ -0,0 +1,78 @@
+package gzap
+
+import (
+	"testing"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+func TestRouteSamplerAllowsInitialThenThereafter(t *testing.T) {
+	s := newRouteSampler()
+	cfg := RouteSampleConfig{Initial: 2, Thereafter: 3, Tick: time.Minute}
+
+	// First Initial requests always pass.
+	for i := 0; i < cfg.Initial; i++ {
+		if !s.allow("k", cfg) {
+			t.Fatalf("request %d: allow = false, want true (within Initial)", i+1)
+		}
+	}
+	// Then only every Thereafter-th request passes.
+	var got []bool
+	for i := 0; i < 6; i++ {
+		got = append(got, s.allow("k", cfg))
+	}
+	want := []bool{false, false, true, false, false, true}
+	for i, g := range got {
+		if g != want[i] {
+			t.Errorf("request %d after Initial: allow = %v, want %v", i+1, g, want[i])
+		}
+	}
+}
+
+func TestRouteSamplerResetsAfterTick(t *testing.T) {
+	s := newRouteSampler()
+	cfg := RouteSampleConfig{Initial: 1, Thereafter: 100, Tick: -time.Millisecond}
+
+	if !s.allow("k", cfg) {
+		t.Fatal("first request should pass")
+	}
+	if !s.allow("k", cfg) {
+		t.Fatal("second request should pass: the negative Tick means the bucket is already expired")
+	}
+}
+
+func TestConfigSampleBypassesErrorsAndFiveXXByDefault(t *testing.T) {
+	cfg := newConfig()
+	cfg.routeSampling = map[string]RouteSampleConfig{
+		"GET /x": {Initial: 0, Thereafter: 1000, Tick: time.Minute},
+	}
+
+	if got := cfg.sample("GET", "/x", zapcore.ErrorLevel, true, 200); got != zapcore.ErrorLevel {
+		t.Errorf("errored request: level = %v, want unchanged %v (errors bypass sampling by default)", got, zapcore.ErrorLevel)
+	}
+	if got := cfg.sample("GET", "/x", zapcore.InfoLevel, false, 503); got != zapcore.InfoLevel {
+		t.Errorf("5xx request: level = %v, want unchanged %v (5xx bypasses sampling by default)", got, zapcore.InfoLevel)
+	}
+}
+
+func TestConfigSampleDowngradesOverBudget(t *testing.T) {
+	cfg := newConfig()
+	cfg.routeSampling = map[string]RouteSampleConfig{
+		"GET /x": {Initial: 0, Thereafter: 2, Tick: time.Minute},
+	}
+
+	if got := cfg.sample("GET", "/x", zapcore.InfoLevel, false, 200); got != zapcore.DebugLevel {
+		t.Errorf("1st over-budget request: level = %v, want %v", got, zapcore.DebugLevel)
+	}
+	if got := cfg.sample("GET", "/x", zapcore.InfoLevel, false, 200); got != zapcore.InfoLevel {
+		t.Errorf("2nd request (Thereafter-th): level = %v, want unchanged %v", got, zapcore.InfoLevel)
+	}
+}
+
+func TestConfigSampleNoRouteSamplingConfiguredIsNoOp(t *testing.T) {
+	cfg := newConfig()
+	if got := cfg.sample("GET", "/unconfigured", zapcore.InfoLevel, false, 200); got != zapcore.InfoLevel {
+		t.Errorf("level = %v, want unchanged %v", got, zapcore.InfoLevel)
+	}
+}
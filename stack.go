@@ -0,0 +1,101 @@
+package gzap
+
+import (
+	"runtime"
+	"strings"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// gzapPkgPrefix identifies frames belonging to this package, skipped by
+// CaptureStack alongside runtime frames.
+const gzapPkgPrefix = "github.com/things-go/gzap."
+
+// Frame is one parsed call-stack frame, as captured by CaptureStack.
+type Frame struct {
+	Func string
+	File string
+	Line int
+	PC   uintptr
+}
+
+// MarshalLogObject implements zapcore.ObjectMarshaler.
+func (f Frame) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddString("func", f.Func)
+	enc.AddString("file", f.File)
+	enc.AddInt("line", f.Line)
+	enc.AddUint64("pc", uint64(f.PC))
+	return nil
+}
+
+// frames implements zapcore.ArrayMarshaler over []Frame, so it can be passed
+// to zap.Array.
+type frames []Frame
+
+// MarshalLogArray implements zapcore.ArrayMarshaler.
+func (fs frames) MarshalLogArray(enc zapcore.ArrayEncoder) error {
+	for _, f := range fs {
+		if err := enc.AppendObject(f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+const (
+	defaultStackSkip  = 3
+	defaultStackDepth = 32
+)
+
+// WithStackSkip optional number of additional innermost frames to skip when
+// capturing a panic's stack, on top of the runtime and gzap frames that
+// CaptureStack always drops.
+// default: 3.
+func WithStackSkip(skip int) Option {
+	return func(c *Config) {
+		c.stackSkip = skip
+	}
+}
+
+// WithStackDepth optional maximum number of frames captured for a panic's
+// stack.
+// default: 32.
+func WithStackDepth(depth int) Option {
+	return func(c *Config) {
+		c.stackDepth = depth
+	}
+}
+
+// CaptureStack captures up to depth call-stack frames via runtime.Callers,
+// skipping the first skip frames as well as any runtime or gzap frames.
+func CaptureStack(skip, depth int) []Frame {
+	if depth <= 0 {
+		depth = defaultStackDepth
+	}
+	pcs := make([]uintptr, depth)
+	n := runtime.Callers(skip+2, pcs)
+	if n == 0 {
+		return nil
+	}
+	callerFrames := runtime.CallersFrames(pcs[:n])
+	out := make([]Frame, 0, n)
+	for {
+		frame, more := callerFrames.Next()
+		if !isRuntimeOrGzapFrame(frame.Function) {
+			out = append(out, Frame{
+				Func: frame.Function,
+				File: frame.File,
+				Line: frame.Line,
+				PC:   frame.PC,
+			})
+		}
+		if !more {
+			break
+		}
+	}
+	return out
+}
+
+func isRuntimeOrGzapFrame(fn string) bool {
+	return strings.HasPrefix(fn, "runtime.") || strings.HasPrefix(fn, gzapPkgPrefix)
+}
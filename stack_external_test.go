@@ -0,0 +1,35 @@
+package gzap_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/things-go/gzap"
+)
+
+// TestCaptureStackFromOutsidePackageKeepsCallerFrame exercises CaptureStack
+// as user code would call it: called from outside the gzap package entirely,
+// the caller's own frame must survive the runtime/gzap filtering, and no
+// runtime.* or github.com/things-go/gzap.* frame (e.g. CaptureStack's own
+// frame) should leak into the result.
+func TestCaptureStackFromOutsidePackageKeepsCallerFrame(t *testing.T) {
+	frames := gzap.CaptureStack(0, 32)
+	if len(frames) == 0 {
+		t.Fatal("CaptureStack returned no frames")
+	}
+
+	top := frames[0]
+	const wantFunc = "github.com/things-go/gzap_test.TestCaptureStackFromOutsidePackageKeepsCallerFrame"
+	if top.Func != wantFunc {
+		t.Errorf("top frame Func = %q, want %q", top.Func, wantFunc)
+	}
+
+	for _, f := range frames {
+		if strings.HasPrefix(f.Func, "runtime.") {
+			t.Errorf("frame %q: runtime frames should be filtered out", f.Func)
+		}
+		if strings.HasPrefix(f.Func, "github.com/things-go/gzap.") {
+			t.Errorf("frame %q: gzap-internal frames should be filtered out", f.Func)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package gzap
+
+import (
+	"testing"
+
+	"go.uber.org/zap/zapcore"
+)
+
+func TestIsRuntimeOrGzapFrame(t *testing.T) {
+	tests := []struct {
+		fn   string
+		want bool
+	}{
+		{"runtime.gopanic", true},
+		{"runtime.main", true},
+		{"github.com/things-go/gzap.Recovery.func1", true},
+		{"github.com/things-go/gzap.CaptureStack", true},
+		{"github.com/things-go/gzap/otelgzap.WithPanicHook", false},
+		{"net/http.HandlerFunc.ServeHTTP", false},
+		{"main.handler", false},
+	}
+	for _, tt := range tests {
+		if got := isRuntimeOrGzapFrame(tt.fn); got != tt.want {
+			t.Errorf("isRuntimeOrGzapFrame(%q) = %v, want %v", tt.fn, got, tt.want)
+		}
+	}
+}
+
+func TestFrameMarshalLogObject(t *testing.T) {
+	f := Frame{Func: "main.handler", File: "/src/main.go", Line: 42, PC: 0xdeadbeef}
+
+	enc := zapcore.NewMapObjectEncoder()
+	if err := f.MarshalLogObject(enc); err != nil {
+		t.Fatalf("MarshalLogObject: %v", err)
+	}
+
+	want := map[string]interface{}{
+		"func": "main.handler",
+		"file": "/src/main.go",
+		"line": 42,
+		"pc":   uint64(0xdeadbeef),
+	}
+	for k, wantVal := range want {
+		if got := enc.Fields[k]; got != wantVal {
+			t.Errorf("field %q = %v (%T), want %v (%T)", k, got, got, wantVal, wantVal)
+		}
+	}
+}
+
+func TestFramesMarshalLogArray(t *testing.T) {
+	fs := frames{
+		{Func: "main.a", File: "a.go", Line: 1},
+		{Func: "main.b", File: "b.go", Line: 2},
+	}
+
+	enc := zapcore.NewMapObjectEncoder()
+	if err := enc.AddArray("stack", fs); err != nil {
+		t.Fatalf("AddArray: %v", err)
+	}
+
+	arr, ok := enc.Fields["stack"].([]interface{})
+	if !ok {
+		t.Fatalf("stack field is %T, want []interface{}", enc.Fields["stack"])
+	}
+	if len(arr) != len(fs) {
+		t.Fatalf("got %d entries, want %d", len(arr), len(fs))
+	}
+}
+
+func TestCaptureStackDepthLimitsFrameCount(t *testing.T) {
+	full := CaptureStack(0, defaultStackDepth)
+	limited := CaptureStack(0, 1)
+
+	if len(limited) > 1 {
+		t.Errorf("len(limited) = %d, want at most 1", len(limited))
+	}
+	if len(full) < len(limited) {
+		t.Errorf("len(full) = %d should be >= len(limited) = %d", len(full), len(limited))
+	}
+}
+
+func TestCaptureStackZeroDepthUsesDefault(t *testing.T) {
+	// depth<=0 falls back to defaultStackDepth rather than capturing nothing.
+	got := CaptureStack(0, 0)
+	if len(got) == 0 {
+		t.Error("CaptureStack(0, 0) returned no frames, want the default depth to apply")
+	}
+}
@@ -0,0 +1,101 @@
+package gzap
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"regexp"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// CtxLoggerKey is the gin.Context key under which Logger stores the
+// request-scoped *zap.Logger.
+const CtxLoggerKey = "gzap.CtxLoggerKey"
+
+// TraceIDHeader is the response header Logger uses to expose the resolved
+// trace/correlation ID back to the client.
+const TraceIDHeader = "X-Trace-Id"
+
+// SpanIDHeader is the response header Logger uses to expose the resolved
+// span ID back to the client.
+const SpanIDHeader = "X-Span-Id"
+
+// Ctx returns the request-scoped *zap.Logger injected by Logger, pre-tagged
+// with method, path, route, client IP and a correlation/trace ID, so that
+// downstream log lines correlate with the access log line emitted by Logger.
+// It returns zap.NewNop() when called on a gin.Context that Logger has not
+// run on.
+func Ctx(c *gin.Context) *zap.Logger {
+	if v, ok := c.Get(CtxLoggerKey); ok {
+		if logger, ok := v.(*zap.Logger); ok {
+			return logger
+		}
+	}
+	return zap.NewNop()
+}
+
+// WithTraceID optional custom correlation/trace ID extraction.
+// default: parse the W3C `traceparent` request header, generating a fresh
+// random trace ID when the header is absent or malformed.
+func WithTraceID(f func(c *gin.Context) string) Option {
+	return func(c *Config) {
+		if f != nil {
+			c.traceID = f
+		}
+	}
+}
+
+// WithSpanID optional custom span ID extraction.
+// default: parse the W3C `traceparent` request header's parent-id, generating
+// a fresh random span ID when the header is absent or malformed.
+func WithSpanID(f func(c *gin.Context) string) Option {
+	return func(c *Config) {
+		if f != nil {
+			c.spanID = f
+		}
+	}
+}
+
+// traceParentRegexp matches a W3C traceparent header:
+// version-trace_id-parent_id-trace_flags, e.g.
+// 00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01
+var traceParentRegexp = regexp.MustCompile(`^[0-9a-f]{2}-([0-9a-f]{32})-([0-9a-f]{16})-[0-9a-f]{2}$`)
+
+// traceID is the default trace ID resolver: it extracts the trace-id from the
+// W3C traceparent header, falling back to a random one when absent or malformed.
+func traceID(c *gin.Context) string {
+	if _, traceID, _, ok := parseTraceParent(c); ok {
+		return traceID
+	}
+	return randomHex(16)
+}
+
+// spanID is the default span ID resolver: it extracts the parent-id from the
+// W3C traceparent header, falling back to a random one when absent or malformed.
+func spanID(c *gin.Context) string {
+	if _, _, spanID, ok := parseTraceParent(c); ok {
+		return spanID
+	}
+	return randomHex(8)
+}
+
+// parseTraceParent parses the request's W3C traceparent header, returning its
+// trace-id and parent-id (span-id) hex strings.
+func parseTraceParent(c *gin.Context) (header, traceID, spanID string, ok bool) {
+	header = c.Request.Header.Get("traceparent")
+	if header == "" {
+		return "", "", "", false
+	}
+	m := traceParentRegexp.FindStringSubmatch(header)
+	if m == nil {
+		return "", "", "", false
+	}
+	return header, m[1], m[2], true
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
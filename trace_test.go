@@ -0,0 +1,112 @@
+package gzap
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func contextWithTraceparent(t *testing.T, header string) *gin.Context {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+	if header != "" {
+		req.Header.Set("traceparent", header)
+	}
+	c.Request = req
+	return c
+}
+
+func TestTraceIDFallsBackToRandomWhenHeaderAbsent(t *testing.T) {
+	c := contextWithTraceparent(t, "")
+	got := traceID(c)
+	if got == "" {
+		t.Fatal("traceID() returned empty string")
+	}
+	if len(got) != 32 {
+		t.Errorf("len(traceID()) = %d, want 32 (16 random bytes, hex-encoded)", len(got))
+	}
+	if traceID(c) == got {
+		t.Error("traceID() returned the same value twice; fallback should be random per call")
+	}
+}
+
+func TestTraceIDFallsBackToRandomWhenHeaderMalformed(t *testing.T) {
+	c := contextWithTraceparent(t, "not-a-valid-traceparent")
+	got := traceID(c)
+	if len(got) != 32 {
+		t.Errorf("len(traceID()) = %d, want 32", len(got))
+	}
+}
+
+func TestTraceIDParsesValidTraceparent(t *testing.T) {
+	c := contextWithTraceparent(t, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	if got, want := traceID(c), "4bf92f3577b34da6a3ce929d0e0e4736"; got != want {
+		t.Errorf("traceID() = %q, want %q", got, want)
+	}
+}
+
+func TestSpanIDFallsBackToRandomWhenHeaderAbsent(t *testing.T) {
+	c := contextWithTraceparent(t, "")
+	got := spanID(c)
+	if len(got) != 16 {
+		t.Errorf("len(spanID()) = %d, want 16 (8 random bytes, hex-encoded)", len(got))
+	}
+	if spanID(c) == got {
+		t.Error("spanID() returned the same value twice; fallback should be random per call")
+	}
+}
+
+func TestSpanIDFallsBackToRandomWhenHeaderMalformed(t *testing.T) {
+	c := contextWithTraceparent(t, "00-tooshort-01")
+	got := spanID(c)
+	if len(got) != 16 {
+		t.Errorf("len(spanID()) = %d, want 16", len(got))
+	}
+}
+
+func TestSpanIDParsesValidTraceparent(t *testing.T) {
+	c := contextWithTraceparent(t, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	if got, want := spanID(c), "00f067aa0ba902b7"; got != want {
+		t.Errorf("spanID() = %q, want %q", got, want)
+	}
+}
+
+func TestWithTraceIDOverridesDefault(t *testing.T) {
+	cfg := newConfig()
+	WithTraceID(func(c *gin.Context) string { return "custom-trace" })(&cfg)
+
+	if got := cfg.traceID(contextWithTraceparent(t, "")); got != "custom-trace" {
+		t.Errorf("cfg.traceID() = %q, want %q", got, "custom-trace")
+	}
+}
+
+func TestWithTraceIDNilIsNoOp(t *testing.T) {
+	cfg := newConfig()
+	WithTraceID(nil)(&cfg)
+
+	if cfg.traceID == nil {
+		t.Fatal("cfg.traceID should remain the default resolver, not nil")
+	}
+}
+
+func TestWithSpanIDOverridesDefault(t *testing.T) {
+	cfg := newConfig()
+	WithSpanID(func(c *gin.Context) string { return "custom-span" })(&cfg)
+
+	if got := cfg.spanID(contextWithTraceparent(t, "")); got != "custom-span" {
+		t.Errorf("cfg.spanID() = %q, want %q", got, "custom-span")
+	}
+}
+
+func TestWithSpanIDNilIsNoOp(t *testing.T) {
+	cfg := newConfig()
+	WithSpanID(nil)(&cfg)
+
+	if cfg.spanID == nil {
+		t.Fatal("cfg.spanID should remain the default resolver, not nil")
+	}
+}